@@ -0,0 +1,69 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maps
+
+import (
+	"context"
+	"time"
+)
+
+// defaultCacheS2Level is the S2 cell level used to key cache entries when
+// CacheOptions.Level is unset. Level 18 cells are about 75m across.
+const defaultCacheS2Level = 18
+
+// Cache is the storage interface behind WithResponseCache. Implementations
+// may be backed by an in-memory LRU (see NewLRUCache), Redis, Bigtable, or
+// anything else that can store and retrieve byte slices by key.
+type Cache interface {
+	// Get returns the cached value for key, if present and not expired.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set stores value under key, expiring it after ttl. A zero ttl means
+	// the entry never expires.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key from the cache, if present.
+	Delete(ctx context.Context, key string) error
+}
+
+// CacheOptions configures how WithResponseCache keys and expires cached
+// results.
+type CacheOptions struct {
+	// Level is the S2 cell level used to key cache entries. Defaults to 18
+	// (~75m cells), a reasonable granularity for fleet/ETA-style repeated
+	// queries near the same points.
+	Level int
+	// TTL is how long a cached element is considered valid.
+	TTL time.Duration
+	// DepartureBucket rounds DepartureTime down to windows of this size
+	// (e.g. 15 minutes) so traffic-aware queries a few seconds apart share
+	// a cache entry. Ignored for requests with no DepartureTime, and for
+	// requests whose DepartureTime isn't "now" or a Unix timestamp.
+	DepartureBucket time.Duration
+}
+
+// WithResponseCache configures a Client to serve DistanceMatrix results
+// from cache, keyed by the S2 cell tokens of the request's origin and
+// destination (among other request parameters), falling back to the
+// configured RoutingProvider or the Google Maps Web Service on a miss.
+// Directions caching is not yet implemented.
+func WithResponseCache(cache Cache, opts CacheOptions) ClientOption {
+	return func(c *Client) error {
+		if opts.Level <= 0 {
+			opts.Level = defaultCacheS2Level
+		}
+		c.cache = cache
+		c.cacheOpts = opts
+		return nil
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package maps provides a wrapper around the Google Maps Web APIs.
+package maps
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// defaultBaseURL is the Google Maps Web Service endpoint used when a request
+// is not routed through an alternate RoutingProvider.
+const defaultBaseURL = "https://maps.googleapis.com"
+
+// Client may be used to make requests to the Google Maps WebService APIs.
+type Client struct {
+	httpClient        *http.Client
+	apiKey            string
+	baseURL           string
+	requestsPerSecond int
+	rateLimiter       *rate.Limiter
+
+	// routingProvider, when set, is used by DistanceMatrix (and, in time,
+	// Directions) in place of the default Google HTTP transport.
+	routingProvider RoutingProvider
+
+	// matrixChunkMax{Origins,Destinations,Elements} bound how a DistanceMatrixRequest with
+	// AutoChunk set is split into sub-requests. They default to Google's
+	// documented Distance Matrix limits and can be overridden with
+	// WithMatrixChunking.
+	matrixChunkMaxOrigins      int
+	matrixChunkMaxDestinations int
+	matrixChunkMaxElements     int
+
+	// cache, when set via WithResponseCache, serves DistanceMatrix results
+	// keyed by S2 cell token instead of calling the RoutingProvider/Google
+	// Maps Web Service on every request.
+	cache     Cache
+	cacheOpts CacheOptions
+
+	// maxRetries bounds how many times a request that fails with a
+	// retryable APIError is retried. See WithMaxRetries.
+	maxRetries int
+}
+
+// ClientOption is the type of constructor options for NewClient(...).
+type ClientOption func(*Client) error
+
+// defaultRequestsPerSecond is the default rate limit, in requests per
+// second, applied to outgoing calls unless overridden with WithRateLimit.
+const defaultRequestsPerSecond = 10
+
+// NewClient constructs a new Client which can make requests to the Google
+// Maps WebService APIs.
+func NewClient(options ...ClientOption) (*Client, error) {
+	c := &Client{
+		requestsPerSecond:          defaultRequestsPerSecond,
+		baseURL:                    defaultBaseURL,
+		matrixChunkMaxOrigins:      defaultMaxMatrixOrigins,
+		matrixChunkMaxDestinations: defaultMaxMatrixDestinations,
+		matrixChunkMaxElements:     defaultMaxMatrixElements,
+		maxRetries:                 defaultMaxRetries,
+	}
+	WithHTTPClient(&http.Client{})(c)
+	for _, option := range options {
+		if err := option(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.apiKey == "" && c.routingProvider == nil {
+		return nil, errors.New("maps: API Key or RoutingProvider required")
+	}
+	c.rateLimiter = rate.NewLimiter(rate.Limit(c.requestsPerSecond), c.requestsPerSecond)
+	return c, nil
+}
+
+// WithAPIKey configures a Maps API client with an API Key.
+func WithAPIKey(apiKey string) ClientOption {
+	return func(c *Client) error {
+		c.apiKey = apiKey
+		return nil
+	}
+}
+
+// WithHTTPClient configures a Maps API client with a http.Client to make requests over.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) error {
+		c.httpClient = httpClient
+		return nil
+	}
+}
+
+// WithRateLimit configures the rate limit, in queries per second, applied to
+// outgoing requests. The default is 10 queries per second.
+func WithRateLimit(queriesPerSecond int) ClientOption {
+	return func(c *Client) error {
+		c.requestsPerSecond = queriesPerSecond
+		return nil
+	}
+}
+
+// awaitRateLimiter blocks until the client's rate limiter allows another
+// request, or ctx is cancelled.
+func (c *Client) awaitRateLimiter(ctx context.Context) error {
+	if c.rateLimiter == nil {
+		return nil
+	}
+	return c.rateLimiter.Wait(ctx)
+}
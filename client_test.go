@@ -0,0 +1,58 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maps
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+// apiKey is a placeholder key used throughout the test suite; it is never
+// sent to a real Google server.
+const apiKey = "AIzaNotReallyAnAPIKey"
+
+// mockServer returns an httptest.Server that responds to every request with
+// the given status code and body.
+func mockServer(code int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+		w.Write([]byte(body))
+	}))
+}
+
+// queryCheckingServer records whether the requests it receives match an
+// expected query string.
+type queryCheckingServer struct {
+	s          *httptest.Server
+	successful int
+	failed     []string
+}
+
+// mockServerForQuery returns a queryCheckingServer that asserts each
+// incoming request's query string equals expectedQuery, responding with code
+// and body regardless of the outcome of that check.
+func mockServerForQuery(expectedQuery string, code int, body string) *queryCheckingServer {
+	server := &queryCheckingServer{}
+	server.s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery == expectedQuery {
+			server.successful++
+		} else {
+			server.failed = append(server.failed, r.URL.RawQuery)
+		}
+		w.WriteHeader(code)
+		w.Write([]byte(body))
+	}))
+	return server
+}
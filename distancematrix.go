@@ -0,0 +1,340 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const distanceMatrixAPI = "/maps/api/distancematrix/json"
+
+// TravelMode is the mode of travel to use when requesting directions or a
+// distance matrix.
+type TravelMode string
+
+// TravelMode options
+const (
+	TravelModeDriving   TravelMode = "driving"
+	TravelModeWalking   TravelMode = "walking"
+	TravelModeBicycling TravelMode = "bicycling"
+	TravelModeTransit   TravelMode = "transit"
+)
+
+func (t TravelMode) String() string {
+	return string(t)
+}
+
+// Avoid is a restriction to honor when requesting directions or a distance
+// matrix.
+type Avoid string
+
+// Avoid options
+const (
+	AvoidTolls    Avoid = "tolls"
+	AvoidHighways Avoid = "highways"
+	AvoidFerries  Avoid = "ferries"
+)
+
+func (a Avoid) String() string {
+	return string(a)
+}
+
+// Units specifies which unit system to use when displaying results.
+type Units string
+
+// Units options
+const (
+	UnitsMetric   Units = "metric"
+	UnitsImperial Units = "imperial"
+)
+
+func (u Units) String() string {
+	return string(u)
+}
+
+// TransitMode specifies one or more preferred modes of transit.
+type TransitMode string
+
+// TransitMode options
+const (
+	TransitModeBus    TransitMode = "bus"
+	TransitModeSubway TransitMode = "subway"
+	TransitModeTrain  TransitMode = "train"
+	TransitModeTram   TransitMode = "tram"
+	TransitModeRail   TransitMode = "rail"
+)
+
+// TransitRoutingPreference biases the transit route returned.
+type TransitRoutingPreference string
+
+// TransitRoutingPreference options
+const (
+	TransitRoutingPreferenceLessWalking    TransitRoutingPreference = "less_walking"
+	TransitRoutingPreferenceFewerTransfers TransitRoutingPreference = "fewer_transfers"
+)
+
+// Distance is the textual and numeric representation of a distance value.
+type Distance struct {
+	// HumanReadable is the distance in a human readable form.
+	HumanReadable string `json:"text"`
+	// Meters is the numeric distance, always in meters.
+	Meters int `json:"value"`
+}
+
+// DistanceMatrixRequest is the request struct for the Distance Matrix API.
+type DistanceMatrixRequest struct {
+	// Origins is a list of addresses and/or textual latitude/longitude values
+	// from which to calculate distance and time.
+	Origins []string
+	// Destinations is a list of addresses and/or textual latitude/longitude
+	// values to which to calculate distance and time.
+	Destinations []string
+	// Mode specifies the travel mode to use when calculating distance.
+	Mode TravelMode
+	// Language specifies the language in which to return results.
+	Language string
+	// Avoid introduces restrictions to the route.
+	Avoid Avoid
+	// Units specifies the unit system to use when expressing distance.
+	Units Units
+	// DepartureTime specifies the desired time of departure, as a string
+	// representing either an integer number of seconds since midnight,
+	// January 1, 1970 UTC, or "now".
+	DepartureTime string
+	// ArrivalTime specifies the desired time of arrival, only valid for
+	// transit directions, as a string representing an integer number of
+	// seconds since midnight, January 1, 1970 UTC.
+	ArrivalTime string
+	// TransitMode specifies one or more preferred modes of transit.
+	TransitMode []TransitMode
+	// TransitRoutingPreference specifies preferences for transit requests.
+	TransitRoutingPreference TransitRoutingPreference
+
+	// AutoChunk, when true, allows the client to transparently split a
+	// request whose origin/destination/element counts exceed Google's
+	// per-request limits into multiple sub-requests. See
+	// WithMatrixChunking.
+	AutoChunk bool
+}
+
+// DistanceMatrixResponse is the response from the Distance Matrix API.
+type DistanceMatrixResponse struct {
+	// OriginAddresses contains an array of addresses as returned by the API
+	// from your original request, formatted and in the proper order.
+	OriginAddresses []string `json:"origin_addresses"`
+	// DestinationAddresses contains an array of addresses as returned by the
+	// API from your original request, formatted and in the proper order.
+	DestinationAddresses []string `json:"destination_addresses"`
+	// Rows contains an array of elements, one for each origin, in the same
+	// order as the origins were supplied.
+	Rows []DistanceMatrixElementsRow `json:"rows"`
+}
+
+// DistanceMatrixElementsRow is a row of DistanceMatrixElements, one per
+// destination, for a single origin.
+type DistanceMatrixElementsRow struct {
+	Elements []*DistanceMatrixElement `json:"elements"`
+}
+
+// DistanceMatrixElement is the travel distance and time for a single
+// origin-destination pairing.
+type DistanceMatrixElement struct {
+	// Status holds the status of this particular element, e.g. "OK",
+	// "NOT_FOUND" or "ZERO_RESULTS".
+	Status string `json:"status"`
+	// Duration is the length of time it takes to travel this route.
+	Duration time.Duration `json:"-"`
+	// DurationInTraffic is the length of time it takes to travel this route
+	// taking into account current traffic conditions.
+	DurationInTraffic time.Duration `json:"-"`
+	// Distance is the distance of this route.
+	Distance Distance `json:"distance"`
+}
+
+// distanceMatrixElement mirrors DistanceMatrixElement's wire format, where
+// Duration is expressed in seconds rather than as a time.Duration.
+type distanceMatrixElement struct {
+	Status            string   `json:"status"`
+	Duration          apiValue `json:"duration"`
+	DurationInTraffic apiValue `json:"duration_in_traffic"`
+	Distance          Distance `json:"distance"`
+}
+
+type apiValue struct {
+	HumanReadable string `json:"text"`
+	Value         int    `json:"value"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, translating the API's
+// seconds-based duration fields into time.Duration.
+func (e *DistanceMatrixElement) UnmarshalJSON(data []byte) error {
+	var raw distanceMatrixElement
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	e.Status = raw.Status
+	e.Distance = raw.Distance
+	e.Duration = time.Duration(raw.Duration.Value) * time.Second
+	e.DurationInTraffic = time.Duration(raw.DurationInTraffic.Value) * time.Second
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, the inverse of UnmarshalJSON: it
+// puts Duration and DurationInTraffic back in the API's seconds-based wire
+// form instead of dropping them via their `json:"-"` tags. This is what lets
+// a DistanceMatrixElement round-trip through the response cache intact.
+func (e DistanceMatrixElement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(distanceMatrixElement{
+		Status:            e.Status,
+		Duration:          apiValue{Value: int(e.Duration / time.Second)},
+		DurationInTraffic: apiValue{Value: int(e.DurationInTraffic / time.Second)},
+		Distance:          e.Distance,
+	})
+}
+
+// DistanceMatrix makes a Distance Matrix API request.
+func (c *Client) DistanceMatrix(ctx context.Context, r *DistanceMatrixRequest) (*DistanceMatrixResponse, error) {
+	if len(r.Origins) == 0 {
+		return nil, errors.New("maps: Origins empty")
+	}
+	if len(r.Destinations) == 0 {
+		return nil, errors.New("maps: Destinations empty")
+	}
+	if r.DepartureTime != "" && r.ArrivalTime != "" {
+		return nil, errors.New("maps: DepartureTime and ArrivalTime both specified")
+	}
+	if len(r.TransitMode) != 0 && r.Mode != TravelModeTransit {
+		return nil, errors.New("maps: TransitMode specified while Mode is not TravelModeTransit")
+	}
+	if r.TransitRoutingPreference != "" && r.Mode != TravelModeTransit {
+		return nil, errors.New("maps: TransitRoutingPreference specified while Mode is not TravelModeTransit")
+	}
+
+	if c.cache != nil {
+		return c.distanceMatrixCached(ctx, r)
+	}
+
+	if r.AutoChunk && c.needsChunking(r) {
+		return c.distanceMatrixChunked(ctx, r)
+	}
+
+	return c.distanceMatrixOnce(ctx, r)
+}
+
+// distanceMatrixOnce issues a single Distance Matrix request, with no
+// chunking, against either the configured RoutingProvider or the Google
+// Maps Web Service.
+func (c *Client) distanceMatrixOnce(ctx context.Context, r *DistanceMatrixRequest) (*DistanceMatrixResponse, error) {
+	if c.routingProvider != nil {
+		return c.routingProvider.DistanceMatrix(ctx, r)
+	}
+
+	if err := c.awaitRateLimiter(ctx); err != nil {
+		return nil, err
+	}
+
+	q := distanceMatrixRequestQuery(r, c.apiKey)
+
+	type distanceMatrixHTTPResponse struct {
+		OriginAddresses      []string                    `json:"origin_addresses"`
+		DestinationAddresses []string                    `json:"destination_addresses"`
+		Rows                 []DistanceMatrixElementsRow `json:"rows"`
+		Status               string                      `json:"status"`
+		ErrorMessage         string                      `json:"error_message"`
+	}
+	var resp distanceMatrixHTTPResponse
+
+	err := withRetry(ctx, c.maxRetries, func() error {
+		resp = distanceMatrixHTTPResponse{}
+		if err := c.get(ctx, distanceMatrixAPI, q, &resp); err != nil {
+			return err
+		}
+		if resp.Status != "" && resp.Status != "OK" {
+			return newAPIErrorFromStatus(resp.Status, resp.ErrorMessage)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DistanceMatrixResponse{
+		OriginAddresses:      resp.OriginAddresses,
+		DestinationAddresses: resp.DestinationAddresses,
+		Rows:                 resp.Rows,
+	}, nil
+}
+
+// distanceMatrixRequestQuery builds the url.Values for a DistanceMatrixRequest.
+func distanceMatrixRequestQuery(r *DistanceMatrixRequest, apiKey string) url.Values {
+	q := make(url.Values)
+	q.Set("origins", strings.Join(r.Origins, "|"))
+	q.Set("destinations", strings.Join(r.Destinations, "|"))
+	q.Set("key", apiKey)
+	if r.Mode != "" {
+		q.Set("mode", r.Mode.String())
+	}
+	if r.Language != "" {
+		q.Set("language", r.Language)
+	}
+	if r.Avoid != "" {
+		q.Set("avoid", r.Avoid.String())
+	}
+	if r.Units != "" {
+		q.Set("units", r.Units.String())
+	}
+	if r.DepartureTime != "" {
+		q.Set("departure_time", r.DepartureTime)
+	}
+	if r.ArrivalTime != "" {
+		q.Set("arrival_time", r.ArrivalTime)
+	}
+	if len(r.TransitMode) != 0 {
+		var modes []string
+		for _, m := range r.TransitMode {
+			modes = append(modes, string(m))
+		}
+		q.Set("transit_mode", strings.Join(modes, "|"))
+	}
+	if r.TransitRoutingPreference != "" {
+		q.Set("transit_routing_preference", string(r.TransitRoutingPreference))
+	}
+	return q
+}
+
+// get issues a single GET request against the Google Maps Web Service and
+// decodes the JSON response body into resp. A non-200 response is returned
+// as a classified *APIError rather than a plain error.
+func (c *Client) get(ctx context.Context, apiPath string, q url.Values, resp interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+apiPath+"?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return newAPIErrorFromHTTP(httpResp)
+	}
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
@@ -0,0 +1,202 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/geo/s2"
+)
+
+// distanceMatrixCached resolves r's origins and destinations to S2 cells,
+// serves any (origin, destination) pair already in cache, and issues one
+// reduced request per origin that still has a cache miss, each covering
+// only that origin's missing destinations. This costs more requests than a
+// single rectangular re-fetch when misses are sparse, but never re-fetches
+// (or re-Sets) a cell that was already a hit.
+func (c *Client) distanceMatrixCached(ctx context.Context, r *DistanceMatrixRequest) (*DistanceMatrixResponse, error) {
+	originLocs, originAddrs, err := c.resolvePlaces(ctx, r.Origins)
+	if err != nil {
+		return nil, fmt.Errorf("maps: resolving origins: %w", err)
+	}
+	destLocs, destAddrs, err := c.resolvePlaces(ctx, r.Destinations)
+	if err != nil {
+		return nil, fmt.Errorf("maps: resolving destinations: %w", err)
+	}
+
+	bucket := departureBucket(r.DepartureTime, c.cacheOpts.DepartureBucket)
+	keyFor := func(i, j int) string {
+		return cacheKeyFor(originLocs[i], destLocs[j], r, bucket, c.cacheOpts.Level)
+	}
+
+	rows := make([]DistanceMatrixElementsRow, len(originLocs))
+	for i := range rows {
+		rows[i].Elements = make([]*DistanceMatrixElement, len(destLocs))
+	}
+
+	// missingDestsByOrigin tracks exactly which destinations are missing for
+	// each origin, so the reduced request below only ever asks Google for
+	// genuinely-missing cells instead of the full cross-product of every
+	// origin and destination that missed at least once.
+	missingDestsByOrigin := map[int][]int{}
+	for i := range originLocs {
+		for j := range destLocs {
+			raw, ok, err := c.cache.Get(ctx, keyFor(i, j))
+			if err == nil && ok {
+				var elem DistanceMatrixElement
+				if err := json.Unmarshal(raw, &elem); err == nil {
+					rows[i].Elements[j] = &elem
+					continue
+				}
+			}
+			missingDestsByOrigin[i] = append(missingDestsByOrigin[i], j)
+		}
+	}
+
+	if len(missingDestsByOrigin) == 0 {
+		return &DistanceMatrixResponse{OriginAddresses: originAddrs, DestinationAddresses: destAddrs, Rows: rows}, nil
+	}
+
+	for _, i := range sortedIntKeys(missingDestsByOrigin) {
+		destIdx := missingDestsByOrigin[i]
+
+		sub := *r
+		sub.Origins = []string{r.Origins[i]}
+		sub.Destinations = selectStrings(r.Destinations, destIdx)
+
+		resp, err := c.distanceMatrixUncached(ctx, &sub)
+		if err != nil {
+			return nil, err
+		}
+		originAddrs[i] = resp.OriginAddresses[0]
+
+		for sj, j := range destIdx {
+			destAddrs[j] = resp.DestinationAddresses[sj]
+			elem := resp.Rows[0].Elements[sj]
+			rows[i].Elements[j] = elem
+			if elem == nil {
+				continue
+			}
+			if raw, err := json.Marshal(elem); err == nil {
+				c.cache.Set(ctx, keyFor(i, j), raw, c.cacheOpts.TTL)
+			}
+		}
+	}
+
+	return &DistanceMatrixResponse{OriginAddresses: originAddrs, DestinationAddresses: destAddrs, Rows: rows}, nil
+}
+
+// distanceMatrixUncached runs r through chunking (if requested and needed)
+// or a single request, with no cache involved. It is what distanceMatrixCached
+// falls back to on a miss.
+func (c *Client) distanceMatrixUncached(ctx context.Context, r *DistanceMatrixRequest) (*DistanceMatrixResponse, error) {
+	if r.AutoChunk && c.needsChunking(r) {
+		return c.distanceMatrixChunked(ctx, r)
+	}
+	return c.distanceMatrixOnce(ctx, r)
+}
+
+// resolvePlaces resolves a list of addresses or "lat,lng" strings to
+// LatLngs, geocoding addresses via c.Geocode as needed.
+func (c *Client) resolvePlaces(ctx context.Context, places []string) ([]LatLng, []string, error) {
+	locs := make([]LatLng, len(places))
+	addrs := make([]string, len(places))
+	for i, place := range places {
+		if lat, lng, ok := parseLatLng(place); ok {
+			locs[i] = LatLng{Lat: lat, Lng: lng}
+			addrs[i] = place
+			continue
+		}
+		results, err := c.Geocode(ctx, &GeocodingRequest{Address: place})
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(results) == 0 {
+			return nil, nil, fmt.Errorf("maps: could not geocode %q", place)
+		}
+		locs[i] = results[0].Geometry.Location
+		addrs[i] = results[0].FormattedAddress
+	}
+	return locs, addrs, nil
+}
+
+// cacheKeyFor builds the cache key for a single (origin, destination) cell
+// of a DistanceMatrixRequest.
+func cacheKeyFor(origin, dest LatLng, r *DistanceMatrixRequest, departureBucket string, level int) string {
+	originCell := s2.CellIDFromLatLng(s2.LatLngFromDegrees(origin.Lat, origin.Lng)).Parent(level).ToToken()
+	destCell := s2.CellIDFromLatLng(s2.LatLngFromDegrees(dest.Lat, dest.Lng)).Parent(level).ToToken()
+
+	transitModes := make([]string, len(r.TransitMode))
+	for i, m := range r.TransitMode {
+		transitModes[i] = string(m)
+	}
+
+	return strings.Join([]string{
+		originCell,
+		destCell,
+		string(r.Mode),
+		string(r.Avoid),
+		string(r.Units),
+		strings.Join(transitModes, ","),
+		string(r.TransitRoutingPreference),
+		departureBucket,
+	}, "|")
+}
+
+// departureBucket rounds departureTime down to a multiple of bucketSize, so
+// that traffic-aware queries close together in time share a cache entry. It
+// understands the "now" sentinel and Unix-second timestamps; anything else
+// is passed through unbucketed so it still participates in the cache key.
+func departureBucket(departureTime string, bucketSize time.Duration) string {
+	if departureTime == "" {
+		return ""
+	}
+	if bucketSize <= 0 {
+		return departureTime
+	}
+
+	var t time.Time
+	if departureTime == "now" {
+		t = time.Now()
+	} else if secs, err := strconv.ParseInt(departureTime, 10, 64); err == nil {
+		t = time.Unix(secs, 0)
+	} else {
+		return departureTime
+	}
+	return strconv.FormatInt(t.Truncate(bucketSize).Unix(), 10)
+}
+
+func sortedIntKeys(m map[int][]int) []int {
+	out := make([]int, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Ints(out)
+	return out
+}
+
+func selectStrings(s []string, idx []int) []string {
+	out := make([]string, len(idx))
+	for i, k := range idx {
+		out[i] = s[k]
+	}
+	return out
+}
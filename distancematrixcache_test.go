@@ -0,0 +1,142 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maps
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDistanceMatrixCacheHitSkipsBackend(t *testing.T) {
+	response := `{
+   "destination_addresses" : [ "Parramatta NSW, Australia" ],
+   "origin_addresses" : [ "Sydney NSW, Australia" ],
+   "rows" : [ { "elements" : [ { "distance" : { "text" : "23.8 km", "value" : 23846 }, "duration" : { "text" : "37 mins", "value" : 2215 }, "status" : "OK" } ] } ],
+   "status" : "OK"
+}`
+	server := mockServer(200, response)
+	defer server.Close()
+
+	c, _ := NewClient(WithAPIKey(apiKey), WithResponseCache(NewLRUCache(100), CacheOptions{}))
+	c.baseURL = server.URL
+
+	r := &DistanceMatrixRequest{
+		Origins:      []string{"-33.8688,151.2093"},
+		Destinations: []string{"-33.8150,151.0011"},
+	}
+
+	first, err := c.DistanceMatrix(context.Background(), r)
+	if err != nil {
+		t.Fatalf("DistanceMatrix returned error: %+v", err)
+	}
+	if first.Rows[0].Elements[0].Status != "OK" {
+		t.Fatalf("unexpected first response: %+v", first)
+	}
+
+	server.Close() // the second call must be served entirely from cache
+
+	second, err := c.DistanceMatrix(context.Background(), r)
+	if err != nil {
+		t.Fatalf("cached DistanceMatrix returned error: %+v", err)
+	}
+	if second.Rows[0].Elements[0].Distance.Meters != first.Rows[0].Elements[0].Distance.Meters {
+		t.Errorf("cached response = %+v, want %+v", second, first)
+	}
+	if second.Rows[0].Elements[0].Duration != first.Rows[0].Elements[0].Duration {
+		t.Errorf("cached Duration = %v, want %v", second.Rows[0].Elements[0].Duration, first.Rows[0].Elements[0].Duration)
+	}
+	if second.Rows[0].Elements[0].Duration != 2215*time.Second {
+		t.Errorf("cached Duration = %v, want %v (duration must survive the cache round-trip)", second.Rows[0].Elements[0].Duration, 2215*time.Second)
+	}
+}
+
+// elementResponseFor writes an OK response for one origin and one or more
+// pipe-separated destinations, one element per destination.
+func elementResponseFor(w http.ResponseWriter, origin, dests string) {
+	destList := strings.Split(dests, "|")
+	addrs := make([]string, len(destList))
+	elements := make([]string, len(destList))
+	for i, d := range destList {
+		addrs[i] = fmt.Sprintf("%q", d)
+		elements[i] = `{ "distance" : { "text" : "1 km", "value" : 1000 }, "duration" : { "text" : "1 min", "value" : 60 }, "status" : "OK" }`
+	}
+	fmt.Fprintf(w, `{
+   "destination_addresses" : [%s],
+   "origin_addresses" : [%q],
+   "rows" : [ { "elements" : [%s] } ],
+   "status" : "OK"
+}`, strings.Join(addrs, ","), origin, strings.Join(elements, ","))
+}
+
+// TestDistanceMatrixCacheSparseMissOnlyFetchesMissingCells warms the cache
+// for the single cell (origins[0], destinations[0]), then requests the full
+// 2x2 matrix and asserts that the sub-request for origins[0] asks only for
+// its genuinely-missing destination, never re-requesting the cell that was
+// already a hit.
+func TestDistanceMatrixCacheSparseMissOnlyFetchesMissingCells(t *testing.T) {
+	warmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		elementResponseFor(w, "-33.8688,151.2093", "-33.8150,151.0011")
+	}))
+	defer warmServer.Close()
+
+	cache := NewLRUCache(100)
+	c, _ := NewClient(WithAPIKey(apiKey), WithResponseCache(cache, CacheOptions{}))
+	c.baseURL = warmServer.URL
+
+	origins := []string{"-33.8688,151.2093", "-31.9505,115.8605"}      // Sydney, Perth
+	destinations := []string{"-33.8150,151.0011", "-33.8688,151.2093"} // Parramatta, Sydney
+
+	// Warm the cache for (origin[0], destinations[0]) only.
+	if _, err := c.DistanceMatrix(context.Background(), &DistanceMatrixRequest{
+		Origins:      origins[:1],
+		Destinations: destinations[:1],
+	}); err != nil {
+		t.Fatalf("warming cache returned error: %+v", err)
+	}
+	warmServer.Close()
+
+	var sawRefetch bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dest := r.URL.Query().Get("destinations")
+		origin := r.URL.Query().Get("origins")
+		if origin == origins[0] && dest != destinations[1] {
+			// origins[0] already has destinations[0] cached; any sub-request
+			// for it must ask only for destinations[1].
+			sawRefetch = true
+		}
+		elementResponseFor(w, origin, dest)
+	}))
+	defer server.Close()
+	c.baseURL = server.URL
+
+	resp, err := c.DistanceMatrix(context.Background(), &DistanceMatrixRequest{
+		Origins:      origins,
+		Destinations: destinations,
+	})
+	if err != nil {
+		t.Fatalf("DistanceMatrix returned error: %+v", err)
+	}
+	if len(resp.Rows) != 2 || len(resp.Rows[0].Elements) != 2 || len(resp.Rows[1].Elements) != 2 {
+		t.Fatalf("unexpected response shape: %+v", resp)
+	}
+	if sawRefetch {
+		t.Error("sub-request re-fetched a destination that was already a cache hit")
+	}
+}
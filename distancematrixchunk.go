@@ -0,0 +1,197 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maps
+
+import (
+	"context"
+	"sync"
+)
+
+// Google's documented Distance Matrix limits: 25 origins, 25 destinations,
+// 100 elements per request.
+const (
+	defaultMaxMatrixOrigins      = 25
+	defaultMaxMatrixDestinations = 25
+	defaultMaxMatrixElements     = 100
+)
+
+// matrixChunkConcurrency bounds how many chunked sub-requests are in flight
+// at once; the client's own rate limiter still throttles the underlying
+// calls, this just caps how many goroutines queue up behind it.
+const matrixChunkConcurrency = 5
+
+// WithMatrixChunking configures the limits a Client applies when splitting
+// a DistanceMatrixRequest with AutoChunk set. The defaults match Google's
+// documented Distance Matrix caps (25 origins, 25 destinations, 100
+// elements); pass smaller values to chunk more aggressively, e.g. to stay
+// under a tighter per-second element quota.
+func WithMatrixChunking(maxOrigins, maxDests, maxElements int) ClientOption {
+	return func(c *Client) error {
+		c.matrixChunkMaxOrigins = maxOrigins
+		c.matrixChunkMaxDestinations = maxDests
+		c.matrixChunkMaxElements = maxElements
+		return nil
+	}
+}
+
+// needsChunking reports whether r exceeds the limits configured on c.
+func (c *Client) needsChunking(r *DistanceMatrixRequest) bool {
+	return len(r.Origins) > c.matrixChunkMaxOrigins ||
+		len(r.Destinations) > c.matrixChunkMaxDestinations ||
+		len(r.Origins)*len(r.Destinations) > c.matrixChunkMaxElements
+}
+
+// matrixChunk is one origin/destination sub-block of a larger
+// DistanceMatrixRequest, along with where its results belong in the
+// stitched-together matrix.
+type matrixChunk struct {
+	originOffset, destOffset int
+	request                  *DistanceMatrixRequest
+}
+
+// distanceMatrixChunked splits r into a grid of sub-requests that each fit
+// within the client's configured limits, runs them concurrently (bounded by
+// matrixChunkConcurrency and the client's rate limiter), and stitches the
+// results back into a single DistanceMatrixResponse with origin/destination
+// order preserved. A sub-request that fails outright is reported as an
+// UNKNOWN_ERROR status on every element it would have populated, rather
+// than failing the whole matrix; a cancelled ctx aborts any chunks still in
+// flight and is returned as the overall error.
+func (c *Client) distanceMatrixChunked(ctx context.Context, r *DistanceMatrixRequest) (*DistanceMatrixResponse, error) {
+	chunks := matrixChunksFor(r, c.matrixChunkMaxOrigins, c.matrixChunkMaxDestinations, c.matrixChunkMaxElements)
+
+	rows := make([]DistanceMatrixElementsRow, len(r.Origins))
+	for i := range rows {
+		rows[i].Elements = make([]*DistanceMatrixElement, len(r.Destinations))
+	}
+	originAddresses := make([]string, len(r.Origins))
+	destAddresses := make([]string, len(r.Destinations))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		sem      = make(chan struct{}, matrixChunkConcurrency)
+		firstErr error
+	)
+
+	for _, chunk := range chunks {
+		chunk := chunk
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = ctx.Err()
+				}
+				mu.Unlock()
+				return
+			}
+			defer func() { <-sem }()
+
+			resp, err := c.distanceMatrixOnce(ctx, chunk.request)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					if firstErr == nil {
+						firstErr = ctxErr
+					}
+					cancel()
+					return
+				}
+				for i := range chunk.request.Origins {
+					for j := range chunk.request.Destinations {
+						rows[chunk.originOffset+i].Elements[chunk.destOffset+j] = &DistanceMatrixElement{Status: "UNKNOWN_ERROR"}
+					}
+				}
+				return
+			}
+
+			for i, addr := range resp.OriginAddresses {
+				originAddresses[chunk.originOffset+i] = addr
+			}
+			for j, addr := range resp.DestinationAddresses {
+				destAddresses[chunk.destOffset+j] = addr
+			}
+			for i, row := range resp.Rows {
+				for j, elem := range row.Elements {
+					rows[chunk.originOffset+i].Elements[chunk.destOffset+j] = elem
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return &DistanceMatrixResponse{
+		OriginAddresses:      originAddresses,
+		DestinationAddresses: destAddresses,
+		Rows:                 rows,
+	}, nil
+}
+
+// matrixChunksFor lays r's origins and destinations out on a grid of
+// sub-requests, each no larger than maxOrigins x maxDests and no more than
+// maxElements cells.
+func matrixChunksFor(r *DistanceMatrixRequest, maxOrigins, maxDests, maxElements int) []matrixChunk {
+	originChunkSize := maxOrigins
+	if originChunkSize > maxElements {
+		originChunkSize = maxElements
+	}
+	if originChunkSize < 1 {
+		originChunkSize = 1
+	}
+
+	destChunkSize := maxDests
+	if originChunkSize*destChunkSize > maxElements {
+		destChunkSize = maxElements / originChunkSize
+		if destChunkSize < 1 {
+			destChunkSize = 1
+		}
+	}
+
+	var chunks []matrixChunk
+	for oStart := 0; oStart < len(r.Origins); oStart += originChunkSize {
+		oEnd := minInt(oStart+originChunkSize, len(r.Origins))
+		for dStart := 0; dStart < len(r.Destinations); dStart += destChunkSize {
+			dEnd := minInt(dStart+destChunkSize, len(r.Destinations))
+
+			sub := *r
+			sub.AutoChunk = false
+			sub.Origins = r.Origins[oStart:oEnd]
+			sub.Destinations = r.Destinations[dStart:dEnd]
+			chunks = append(chunks, matrixChunk{originOffset: oStart, destOffset: dStart, request: &sub})
+		}
+	}
+	return chunks
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,168 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maps
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDistanceMatrixAutoChunkBelowLimitsSkipsChunking(t *testing.T) {
+	response := `{
+   "destination_addresses" : [ "Parramatta NSW, Australia" ],
+   "origin_addresses" : [ "Sydney NSW, Australia", "Pyrmont NSW, Australia" ],
+   "rows" : [
+      { "elements" : [ { "distance" : { "text" : "23.8 km", "value" : 23846 }, "duration" : { "text" : "37 mins", "value" : 2215 }, "status" : "OK" } ] },
+      { "elements" : [ { "distance" : { "text" : "22.2 km", "value" : 22242 }, "duration" : { "text" : "34 mins", "value" : 2058 }, "status" : "OK" } ] }
+   ],
+   "status" : "OK"
+}`
+	server := mockServer(200, response)
+	defer server.Close()
+	c, _ := NewClient(WithAPIKey(apiKey))
+	c.baseURL = server.URL
+
+	r := &DistanceMatrixRequest{
+		Origins:      []string{"Sydney", "Pyrmont"},
+		Destinations: []string{"Parramatta"},
+		AutoChunk:    true,
+	}
+
+	if c.needsChunking(r) {
+		t.Fatal("a 2x1 matrix should not need chunking under the default limits")
+	}
+
+	resp, err := c.DistanceMatrix(context.Background(), r)
+	if err != nil {
+		t.Fatalf("DistanceMatrix returned error: %+v", err)
+	}
+	if len(resp.Rows) != 2 || len(resp.Rows[0].Elements) != 1 {
+		t.Fatalf("unexpected response shape: %+v", resp)
+	}
+	if resp.Rows[0].Elements[0].Status != "OK" {
+		t.Errorf("Status = %q, want OK", resp.Rows[0].Elements[0].Status)
+	}
+}
+
+// distanceMatrixElementServer answers each sub-request with a single OK
+// element whose distance encodes the one origin and one destination it
+// carries, except for a configurable (origin, destination) pair which fails
+// outright, so tests can check that the stitched matrix both preserves
+// origin/destination order and surfaces a per-cell error.
+func distanceMatrixElementServer(failOrigin, failDest string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.URL.Query().Get("origins")
+		dest := r.URL.Query().Get("destinations")
+		if origin == failOrigin && dest == failDest {
+			// A 4xx response classifies as non-retryable, so this sub-request
+			// fails immediately rather than exhausting the retry budget.
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		meters := 1000 * (len(origin) + len(dest))
+		fmt.Fprintf(w, `{
+			"status": "OK",
+			"origin_addresses": [%q],
+			"destination_addresses": [%q],
+			"rows": [ { "elements": [ { "status": "OK", "distance": { "text": "x", "value": %d }, "duration": { "text": "x", "value": 1 } } ] } ]
+		}`, origin, dest, meters)
+	}))
+}
+
+func TestDistanceMatrixAutoChunkSplitsAndStitchesInOrder(t *testing.T) {
+	server := distanceMatrixElementServer("Pyrmont", "Perth")
+	defer server.Close()
+
+	// WithMatrixChunking(1, 1, 1) forces every origin/destination pair into
+	// its own sub-request, well below this request's 2x2 = 4 elements.
+	c, _ := NewClient(WithAPIKey(apiKey), WithMatrixChunking(1, 1, 1))
+	c.baseURL = server.URL
+
+	r := &DistanceMatrixRequest{
+		Origins:      []string{"Sydney", "Pyrmont"},
+		Destinations: []string{"Parramatta", "Perth"},
+		AutoChunk:    true,
+	}
+
+	if !c.needsChunking(r) {
+		t.Fatal("a 2x2 matrix should need chunking with WithMatrixChunking(1, 1, 1)")
+	}
+
+	resp, err := c.DistanceMatrix(context.Background(), r)
+	if err != nil {
+		t.Fatalf("DistanceMatrix returned error: %+v", err)
+	}
+	if len(resp.Rows) != 2 || len(resp.Rows[0].Elements) != 2 || len(resp.Rows[1].Elements) != 2 {
+		t.Fatalf("unexpected response shape: %+v", resp)
+	}
+
+	wantMeters := func(origin, dest string) int {
+		return 1000 * (len(origin) + len(dest))
+	}
+
+	type cell struct {
+		i, j         int
+		origin, dest string
+		wantFailed   bool
+	}
+	for _, c := range []cell{
+		{0, 0, "Sydney", "Parramatta", false},
+		{0, 1, "Sydney", "Perth", false},
+		{1, 0, "Pyrmont", "Parramatta", false},
+		{1, 1, "Pyrmont", "Perth", true},
+	} {
+		elem := resp.Rows[c.i].Elements[c.j]
+		if c.wantFailed {
+			if elem.Status != "UNKNOWN_ERROR" {
+				t.Errorf("Rows[%d].Elements[%d].Status = %q, want UNKNOWN_ERROR", c.i, c.j, elem.Status)
+			}
+			continue
+		}
+		if elem.Status != "OK" {
+			t.Errorf("Rows[%d].Elements[%d].Status = %q, want OK", c.i, c.j, elem.Status)
+		}
+		if want := wantMeters(c.origin, c.dest); elem.Distance.Meters != want {
+			t.Errorf("Rows[%d].Elements[%d].Distance.Meters = %d, want %d (order not preserved?)", c.i, c.j, elem.Distance.Meters, want)
+		}
+	}
+}
+
+func TestDistanceMatrixAutoChunkAbortsOnContextCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, `{"status":"OK","origin_addresses":["o"],"destination_addresses":["d"],"rows":[{"elements":[{"status":"OK","distance":{"text":"x","value":1},"duration":{"text":"x","value":1}}]}]}`)
+	}))
+	defer server.Close()
+
+	c, _ := NewClient(WithAPIKey(apiKey), WithMatrixChunking(1, 1, 1))
+	c.baseURL = server.URL
+
+	r := &DistanceMatrixRequest{
+		Origins:      []string{"Sydney", "Pyrmont"},
+		Destinations: []string{"Parramatta", "Perth"},
+		AutoChunk:    true,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.DistanceMatrix(ctx, r); err == nil {
+		t.Error("expected a cancelled context to abort the chunked request with an error")
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maps
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// APIError represents a failure calling a Google Maps Web Service endpoint:
+// either a non-OK status in an otherwise successful HTTP response, or an
+// HTTP-level failure that never got as far as a JSON body. Retryable and
+// RetryAfter tell the client's retry loop whether, and how long, to wait
+// before trying again.
+type APIError struct {
+	// Status is the API's status string, e.g. "OVER_QUERY_LIMIT". Empty for
+	// HTTP-level errors that never produced a status.
+	Status string
+	// ErrorMessage is the API's human-readable error_message field, if any.
+	ErrorMessage string
+	// HTTPStatus is the HTTP status code of a non-200 response. It is 0 for
+	// an error built from the status field of a 200 response.
+	HTTPStatus int
+	// Retryable reports whether the same request is worth retrying.
+	Retryable bool
+	// RetryAfter is how long to wait before retrying, taken from the
+	// response's Retry-After header when the server sent one.
+	RetryAfter time.Duration
+}
+
+// Error implements error.
+func (e *APIError) Error() string {
+	switch {
+	case e.Status != "" && e.ErrorMessage != "":
+		return fmt.Sprintf("maps: %s: %s", e.Status, e.ErrorMessage)
+	case e.Status != "":
+		return fmt.Sprintf("maps: %s", e.Status)
+	default:
+		return fmt.Sprintf("maps: HTTP %d", e.HTTPStatus)
+	}
+}
+
+// Is lets callers write errors.Is(err, &maps.APIError{Status: "INVALID_REQUEST"})
+// to check for a particular status without caring about ErrorMessage,
+// HTTPStatus, or retry metadata.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	return e.Status == t.Status
+}
+
+// retryableStatuses classifies the Google-documented Distance Matrix
+// statuses that indicate a transient server-side condition, as opposed to a
+// malformed or unauthorized request that will never succeed on retry.
+var retryableStatuses = map[string]bool{
+	"OVER_QUERY_LIMIT": true,
+	"UNKNOWN_ERROR":    true,
+}
+
+// newAPIErrorFromStatus classifies a Google Maps Web Service status (and
+// optional error_message) into an APIError.
+func newAPIErrorFromStatus(status, errorMessage string) *APIError {
+	return &APIError{
+		Status:       status,
+		ErrorMessage: errorMessage,
+		Retryable:    retryableStatuses[status],
+	}
+}
+
+// newAPIErrorFromHTTP classifies an HTTP-level failure, one with no JSON
+// status available, into an APIError. 429 and 5xx responses are retryable;
+// everything else is treated as permanent.
+func newAPIErrorFromHTTP(resp *http.Response) *APIError {
+	return &APIError{
+		HTTPStatus: resp.StatusCode,
+		Retryable:  resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+// parseRetryAfter parses a Retry-After header, which may be either a
+// number of seconds or an HTTP-date, returning 0 if it is absent or
+// unparseable.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// ElementError is returned by DistanceMatrixElement.Err for a per-element
+// Status other than "OK", e.g. "ZERO_RESULTS", "NOT_FOUND", or
+// "MAX_ROUTE_LENGTH_EXCEEDED". Unlike APIError, an element error always
+// means this particular origin/destination pair failed; it says nothing
+// about the rest of the matrix.
+type ElementError struct {
+	Status string
+}
+
+// Error implements error.
+func (e *ElementError) Error() string {
+	return fmt.Sprintf("maps: element status %s", e.Status)
+}
+
+// Is lets callers write errors.Is(elem.Err(), &maps.ElementError{Status: "ZERO_RESULTS"}).
+func (e *ElementError) Is(target error) bool {
+	t, ok := target.(*ElementError)
+	if !ok {
+		return false
+	}
+	return e.Status == t.Status
+}
+
+// Err returns nil if the element's Status is "OK" (or unset, as in a
+// request that never reached Google), and an *ElementError describing the
+// failure otherwise.
+func (e *DistanceMatrixElement) Err() error {
+	if e.Status == "" || e.Status == "OK" {
+		return nil
+	}
+	return &ElementError{Status: e.Status}
+}
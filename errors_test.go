@@ -0,0 +1,102 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maps
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDistanceMatrixRetriesOverQueryLimit(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Write([]byte(`{"status":"OVER_QUERY_LIMIT"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"OK","origin_addresses":["Sydney"],"destination_addresses":["Parramatta"],"rows":[{"elements":[{"status":"OK","distance":{"text":"1 km","value":1000},"duration":{"text":"1 min","value":60}}]}]}`))
+	}))
+	defer server.Close()
+
+	c, _ := NewClient(WithAPIKey(apiKey))
+	c.baseURL = server.URL
+
+	resp, err := c.DistanceMatrix(context.Background(), &DistanceMatrixRequest{
+		Origins:      []string{"Sydney"},
+		Destinations: []string{"Parramatta"},
+	})
+	if err != nil {
+		t.Fatalf("DistanceMatrix returned error: %+v", err)
+	}
+	if atomic.LoadInt32(&attempts) != 2 {
+		t.Errorf("attempts = %d, want 2 (one OVER_QUERY_LIMIT retry)", attempts)
+	}
+	if resp.Rows[0].Elements[0].Status != "OK" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestDistanceMatrixDoesNotRetryInvalidRequest(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Write([]byte(`{"status":"INVALID_REQUEST"}`))
+	}))
+	defer server.Close()
+
+	c, _ := NewClient(WithAPIKey(apiKey))
+	c.baseURL = server.URL
+
+	_, err := c.DistanceMatrix(context.Background(), &DistanceMatrixRequest{
+		Origins:      []string{"Sydney"},
+		Destinations: []string{"Parramatta"},
+	})
+	if err == nil {
+		t.Fatal("expected an error for INVALID_REQUEST")
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %T, want *APIError", err)
+	}
+	if apiErr.Retryable {
+		t.Error("INVALID_REQUEST should not be retryable")
+	}
+	if !errors.Is(err, &APIError{Status: "INVALID_REQUEST"}) {
+		t.Error("errors.Is should match on Status")
+	}
+	if atomic.LoadInt32(&attempts) != 1 {
+		t.Errorf("attempts = %d, want 1 (no retry for a permanent error)", attempts)
+	}
+}
+
+func TestDistanceMatrixElementErr(t *testing.T) {
+	ok := &DistanceMatrixElement{Status: "OK"}
+	if err := ok.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil for status OK", err)
+	}
+
+	zero := &DistanceMatrixElement{Status: "ZERO_RESULTS"}
+	err := zero.Err()
+	if err == nil {
+		t.Fatal("Err() = nil, want non-nil for status ZERO_RESULTS")
+	}
+	if !errors.Is(err, &ElementError{Status: "ZERO_RESULTS"}) {
+		t.Error("errors.Is should match on Status")
+	}
+}
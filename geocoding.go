@@ -0,0 +1,74 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maps
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+const geocodingAPI = "/maps/api/geocode/json"
+
+// LatLng represents a point on the Earth's surface.
+type LatLng struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// GeocodingRequest is the request struct for the Geocoding API.
+type GeocodingRequest struct {
+	// Address is the street address to geocode.
+	Address string
+	// Language specifies the language in which to return results.
+	Language string
+}
+
+// GeocodingResult is a single result returned by the Geocoding API.
+type GeocodingResult struct {
+	FormattedAddress string `json:"formatted_address"`
+	Geometry         struct {
+		Location LatLng `json:"location"`
+	} `json:"geometry"`
+}
+
+// Geocode makes a Geocoding API request.
+func (c *Client) Geocode(ctx context.Context, r *GeocodingRequest) ([]GeocodingResult, error) {
+	if r.Address == "" {
+		return nil, errors.New("maps: Address empty")
+	}
+	if err := c.awaitRateLimiter(ctx); err != nil {
+		return nil, err
+	}
+
+	q := make(url.Values)
+	q.Set("address", r.Address)
+	q.Set("key", c.apiKey)
+	if r.Language != "" {
+		q.Set("language", r.Language)
+	}
+
+	var resp struct {
+		Results []GeocodingResult `json:"results"`
+		Status  string            `json:"status"`
+	}
+	if err := c.get(ctx, geocodingAPI, q, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status != "" && resp.Status != "OK" && resp.Status != "ZERO_RESULTS" {
+		return nil, errors.New("maps: " + resp.Status)
+	}
+	return resp.Results, nil
+}
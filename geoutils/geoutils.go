@@ -0,0 +1,151 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package geoutils provides small geometry helpers for working with the
+// decoded polylines returned by the Directions and Distance Matrix APIs,
+// without depending on a full geo library: projecting a point onto a route
+// and measuring distance along it.
+package geoutils
+
+import (
+	"math"
+
+	"googlemaps.github.io/maps"
+)
+
+// earthRadiusMeters is the mean radius of the Earth, used for the
+// Haversine and equirectangular-projection calculations below.
+const earthRadiusMeters = 6371000
+
+// SnappedPoint is the result of snapping a single point onto a route.
+type SnappedPoint struct {
+	// Original is the point as given to SnapToRoute.
+	Original maps.LatLng
+	// Projected is Original projected onto the closest segment of the route.
+	Projected maps.LatLng
+	// SegmentIndex is the index, within the route's polyline, of the
+	// segment's first vertex: the closest segment runs from
+	// poly[SegmentIndex] to poly[SegmentIndex+1].
+	SegmentIndex int
+	// DistanceMeters is the distance from Original to Projected.
+	DistanceMeters float64
+}
+
+// DistanceFromPolyline returns the distance in meters from point to its
+// closest projection onto any segment of poly, the index of that segment's
+// first vertex, and the projected point itself. It returns (+Inf, -1,
+// maps.LatLng{}) for an empty poly.
+func DistanceFromPolyline(point maps.LatLng, poly []maps.LatLng) (meters float64, segmentIndex int, projected maps.LatLng) {
+	switch len(poly) {
+	case 0:
+		return math.Inf(1), -1, maps.LatLng{}
+	case 1:
+		return haversine(point, poly[0]), 0, poly[0]
+	}
+
+	meters = math.Inf(1)
+	for i := 0; i+1 < len(poly); i++ {
+		d, p := distanceToSegment(point, poly[i], poly[i+1])
+		if d < meters {
+			meters = d
+			segmentIndex = i
+			projected = p
+		}
+	}
+	return meters, segmentIndex, projected
+}
+
+// SnapToRoute snaps each of points onto the closest segment of poly, the
+// decoded overview polyline of a route. Callers can turn SegmentIndex and
+// Projected into "how far along the route is this point" using
+// CumulativeDistances.
+//
+// poly takes a decoded []maps.LatLng rather than a maps.Route because this
+// package has no Route/Directions type yet; callers on a DirectionsResult
+// should decode its OverviewPolyline themselves and pass the result here.
+// Once a Route type lands, a thin wrapper can take it directly without
+// changing this signature.
+func SnapToRoute(points []maps.LatLng, poly []maps.LatLng) []SnappedPoint {
+	snapped := make([]SnappedPoint, len(points))
+	for i, pt := range points {
+		meters, segmentIndex, projected := DistanceFromPolyline(pt, poly)
+		snapped[i] = SnappedPoint{
+			Original:       pt,
+			Projected:      projected,
+			SegmentIndex:   segmentIndex,
+			DistanceMeters: meters,
+		}
+	}
+	return snapped
+}
+
+// CumulativeDistances returns, for each vertex of poly, the Haversine
+// distance traveled from poly[0] up to and including that vertex: the
+// result's first element is always 0, and its i-th element is the distance
+// along poly from its start to poly[i].
+func CumulativeDistances(poly []maps.LatLng) []float64 {
+	cumulative := make([]float64, len(poly))
+	for i := 1; i < len(poly); i++ {
+		cumulative[i] = cumulative[i-1] + haversine(poly[i-1], poly[i])
+	}
+	return cumulative
+}
+
+// haversine returns the great-circle distance between a and b in meters.
+func haversine(a, b maps.LatLng) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := radians(b.Lat - a.Lat)
+	dLng := radians(b.Lng - a.Lng)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(h))
+}
+
+// distanceToSegment projects point onto the segment [a, b] using an
+// equirectangular projection centered on a, which is accurate enough for
+// the short segments that make up a decoded route polyline, and returns the
+// cross-track distance in meters along with the projected point.
+func distanceToSegment(point, a, b maps.LatLng) (float64, maps.LatLng) {
+	cosLat := math.Cos(radians(a.Lat))
+	bx := radians(b.Lng-a.Lng) * cosLat * earthRadiusMeters
+	by := radians(b.Lat-a.Lat) * earthRadiusMeters
+	px := radians(point.Lng-a.Lng) * cosLat * earthRadiusMeters
+	py := radians(point.Lat-a.Lat) * earthRadiusMeters
+
+	lengthSq := bx*bx + by*by
+
+	var t float64
+	if lengthSq > 0 {
+		t = (px*bx + py*by) / lengthSq
+		if t < 0 {
+			t = 0
+		} else if t > 1 {
+			t = 1
+		}
+	}
+
+	projX, projY := t*bx, t*by
+	distance := math.Hypot(px-projX, py-projY)
+
+	projected := maps.LatLng{
+		Lat: a.Lat + (b.Lat-a.Lat)*t,
+		Lng: a.Lng + (b.Lng-a.Lng)*t,
+	}
+	return distance, projected
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
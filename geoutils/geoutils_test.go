@@ -0,0 +1,76 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoutils
+
+import (
+	"math"
+	"testing"
+
+	"googlemaps.github.io/maps"
+)
+
+func TestDistanceFromPolylineOnSegment(t *testing.T) {
+	poly := []maps.LatLng{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}, {Lat: 0, Lng: 2}}
+	point := maps.LatLng{Lat: 0.001, Lng: 1.5}
+
+	meters, segmentIndex, projected := DistanceFromPolyline(point, poly)
+
+	if segmentIndex != 1 {
+		t.Errorf("segmentIndex = %d, want 1", segmentIndex)
+	}
+	if want := 111.0; math.Abs(meters-want) > 5 {
+		t.Errorf("meters = %v, want ~%v", meters, want)
+	}
+	if math.Abs(projected.Lng-1.5) > 0.01 || math.Abs(projected.Lat) > 0.01 {
+		t.Errorf("projected = %+v, want ~{Lat:0 Lng:1.5}", projected)
+	}
+}
+
+func TestDistanceFromPolylineEmpty(t *testing.T) {
+	meters, segmentIndex, _ := DistanceFromPolyline(maps.LatLng{}, nil)
+	if !math.IsInf(meters, 1) || segmentIndex != -1 {
+		t.Errorf("got (%v, %d), want (+Inf, -1) for an empty polyline", meters, segmentIndex)
+	}
+}
+
+func TestSnapToRoute(t *testing.T) {
+	poly := []maps.LatLng{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}}
+	points := []maps.LatLng{{Lat: 0.001, Lng: 0.5}}
+
+	snapped := SnapToRoute(points, poly)
+
+	if len(snapped) != 1 {
+		t.Fatalf("len(snapped) = %d, want 1", len(snapped))
+	}
+	if snapped[0].SegmentIndex != 0 {
+		t.Errorf("SegmentIndex = %d, want 0", snapped[0].SegmentIndex)
+	}
+	if snapped[0].Original != points[0] {
+		t.Errorf("Original = %+v, want %+v", snapped[0].Original, points[0])
+	}
+}
+
+func TestCumulativeDistances(t *testing.T) {
+	poly := []maps.LatLng{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}, {Lat: 0, Lng: 2}}
+
+	got := CumulativeDistances(poly)
+
+	if len(got) != 3 || got[0] != 0 {
+		t.Fatalf("CumulativeDistances = %v, want 3 values starting at 0", got)
+	}
+	if got[2] <= got[1] {
+		t.Errorf("cumulative distance should increase monotonically along the polyline: %v", got)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maps
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRUCacheGetSetDelete(t *testing.T) {
+	c := NewLRUCache(10)
+	ctx := context.Background()
+
+	if _, ok, _ := c.Get(ctx, "missing"); ok {
+		t.Error("Get on an empty cache should miss")
+	}
+
+	if err := c.Set(ctx, "a", []byte("1"), 0); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	if value, ok, _ := c.Get(ctx, "a"); !ok || string(value) != "1" {
+		t.Errorf("Get(a) = %q, %v, want 1, true", value, ok)
+	}
+
+	c.Delete(ctx, "a")
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Error("Get after Delete should miss")
+	}
+}
+
+func TestLRUCacheExpiry(t *testing.T) {
+	c := NewLRUCache(10)
+	ctx := context.Background()
+
+	c.Set(ctx, "a", []byte("1"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Error("Get should miss once the TTL has elapsed")
+	}
+}
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRUCache(2)
+	ctx := context.Background()
+
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), 0)
+	c.Get(ctx, "a") // touch a so b is the least-recently-used entry
+	c.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("b should have been evicted")
+	}
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Error("a should still be cached")
+	}
+	if _, ok, _ := c.Get(ctx, "c"); !ok {
+		t.Error("c should still be cached")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maps
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// defaultMaxRetries bounds how many times a retryable request is retried
+// before its error is returned to the caller.
+const defaultMaxRetries = 3
+
+// baseBackoff is the starting point for jittered exponential backoff
+// between retries, doubling on each attempt.
+const baseBackoff = 500 * time.Millisecond
+
+// WithMaxRetries overrides how many times a request that fails with a
+// retryable APIError is retried. The default is 3.
+func WithMaxRetries(maxRetries int) ClientOption {
+	return func(c *Client) error {
+		c.maxRetries = maxRetries
+		return nil
+	}
+}
+
+// backoffFor returns how long to wait before retry attempt n (0-indexed).
+// It honors retryAfter when the server specified one explicitly, and
+// otherwise uses jittered exponential backoff.
+func backoffFor(n int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	base := baseBackoff << uint(n)
+	return base/2 + time.Duration(rand.Int63n(int64(base)/2+1))
+}
+
+// withRetry calls fn until it succeeds, fails with a non-retryable
+// *APIError, or the retry budget is exhausted. ctx cancellation aborts the
+// wait between attempts immediately.
+func withRetry(ctx context.Context, maxRetries int, fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+		apiErr, ok := err.(*APIError)
+		if !ok || !apiErr.Retryable || attempt == maxRetries {
+			return err
+		}
+		select {
+		case <-time.After(backoffFor(attempt, apiErr.RetryAfter)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
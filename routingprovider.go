@@ -0,0 +1,40 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maps
+
+import "context"
+
+// RoutingProvider is the transport behind Client.DistanceMatrix (and, in
+// time, Client.Directions). The default Client talks to the Google Maps Web
+// Service directly; WithRoutingProvider lets callers substitute a
+// self-hostable routing engine such as Valhalla or OSRM without changing the
+// DistanceMatrixRequest/DistanceMatrixResponse types they already use.
+type RoutingProvider interface {
+	// DistanceMatrix computes travel distance and time for every
+	// origin/destination pair in r.
+	DistanceMatrix(ctx context.Context, r *DistanceMatrixRequest) (*DistanceMatrixResponse, error)
+}
+
+// WithRoutingProvider configures a Maps API client to route DistanceMatrix
+// (and, in time, Directions) requests through provider instead of the
+// Google Maps Web Service. It may be combined with WithAPIKey so a provider
+// can still use the Google Geocoding API to resolve string origins and
+// destinations.
+func WithRoutingProvider(provider RoutingProvider) ClientOption {
+	return func(c *Client) error {
+		c.routingProvider = provider
+		return nil
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maps
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValhallaProvider is a RoutingProvider backed by a self-hosted Valhalla
+// (https://github.com/valhalla/valhalla) instance, reachable over its
+// sources_to_targets HTTP API. It lets callers run fully offline or
+// self-hosted routing without changing how they call DistanceMatrix.
+type ValhallaProvider struct {
+	baseURL    string
+	httpClient *http.Client
+	geocoder   *Client
+}
+
+// ValhallaOption configures a ValhallaProvider.
+type ValhallaOption func(*ValhallaProvider)
+
+// WithValhallaHTTPClient overrides the http.Client used to talk to Valhalla.
+func WithValhallaHTTPClient(httpClient *http.Client) ValhallaOption {
+	return func(p *ValhallaProvider) {
+		p.httpClient = httpClient
+	}
+}
+
+// WithValhallaGeocoder supplies a Client used to resolve string origins and
+// destinations to coordinates before they are sent to Valhalla, which only
+// understands lat/lng. Required unless every Origins/Destinations entry is
+// already a "lat,lng" pair.
+func WithValhallaGeocoder(geocoder *Client) ValhallaOption {
+	return func(p *ValhallaProvider) {
+		p.geocoder = geocoder
+	}
+}
+
+// NewValhallaProvider returns a RoutingProvider that sends matrix requests
+// to the Valhalla instance at baseURL, e.g. "http://localhost:8002".
+func NewValhallaProvider(baseURL string, opts ...ValhallaOption) *ValhallaProvider {
+	p := &ValhallaProvider{baseURL: strings.TrimRight(baseURL, "/"), httpClient: &http.Client{}}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+type valhallaLocation struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type valhallaDateTime struct {
+	Type  int    `json:"type"`
+	Value string `json:"value"`
+}
+
+type valhallaMatrixRequest struct {
+	Sources        []valhallaLocation     `json:"sources"`
+	Targets        []valhallaLocation     `json:"targets"`
+	Costing        string                 `json:"costing"`
+	CostingOptions map[string]interface{} `json:"costing_options,omitempty"`
+	DateTime       *valhallaDateTime      `json:"date_time,omitempty"`
+}
+
+// valhallaMatrixCell mirrors one sources_to_targets matrix cell. Distance
+// and Time are pointers because Valhalla represents an unreachable pair as
+// a populated cell with both fields set to JSON null, not as a null cell;
+// distinguishing "zero" from "absent" is exactly what matters here.
+type valhallaMatrixCell struct {
+	Distance *float64 `json:"distance"` // kilometers
+	Time     *float64 `json:"time"`     // seconds
+}
+
+type valhallaMatrixResponse struct {
+	SourcesToTargets [][]*valhallaMatrixCell `json:"sources_to_targets"`
+}
+
+// DistanceMatrix implements RoutingProvider by translating r into a
+// Valhalla sources_to_targets request and mapping the resulting matrix back
+// onto a DistanceMatrixResponse.
+func (p *ValhallaProvider) DistanceMatrix(ctx context.Context, r *DistanceMatrixRequest) (*DistanceMatrixResponse, error) {
+	sources, originAddrs, err := p.resolve(ctx, r.Origins)
+	if err != nil {
+		return nil, fmt.Errorf("maps: resolving origins: %w", err)
+	}
+	targets, destAddrs, err := p.resolve(ctx, r.Destinations)
+	if err != nil {
+		return nil, fmt.Errorf("maps: resolving destinations: %w", err)
+	}
+
+	body, err := json.Marshal(valhallaMatrixRequest{
+		Sources:        sources,
+		Targets:        targets,
+		Costing:        valhallaCosting(r.Mode),
+		CostingOptions: valhallaCostingOptions(r),
+		DateTime:       valhallaDateTimeFor(r.DepartureTime),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/sources_to_targets", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("maps: valhalla returned HTTP %d", httpResp.StatusCode)
+	}
+
+	var matrix valhallaMatrixResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&matrix); err != nil {
+		return nil, err
+	}
+
+	rows := make([]DistanceMatrixElementsRow, len(matrix.SourcesToTargets))
+	for i, row := range matrix.SourcesToTargets {
+		elements := make([]*DistanceMatrixElement, len(row))
+		for j, cell := range row {
+			elements[j] = valhallaElement(cell)
+		}
+		rows[i] = DistanceMatrixElementsRow{Elements: elements}
+	}
+
+	return &DistanceMatrixResponse{
+		OriginAddresses:      originAddrs,
+		DestinationAddresses: destAddrs,
+		Rows:                 rows,
+	}, nil
+}
+
+// resolve converts a list of addresses or "lat,lng" strings into Valhalla
+// locations, geocoding addresses via p.geocoder as needed.
+func (p *ValhallaProvider) resolve(ctx context.Context, places []string) ([]valhallaLocation, []string, error) {
+	locations := make([]valhallaLocation, len(places))
+	addresses := make([]string, len(places))
+	for i, place := range places {
+		if lat, lng, ok := parseLatLng(place); ok {
+			locations[i] = valhallaLocation{Lat: lat, Lon: lng}
+			addresses[i] = place
+			continue
+		}
+		if p.geocoder == nil {
+			return nil, nil, fmt.Errorf("maps: %q is not a \"lat,lng\" pair and no geocoder is configured", place)
+		}
+		results, err := p.geocoder.Geocode(ctx, &GeocodingRequest{Address: place})
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(results) == 0 {
+			return nil, nil, fmt.Errorf("maps: could not geocode %q", place)
+		}
+		loc := results[0].Geometry.Location
+		locations[i] = valhallaLocation{Lat: loc.Lat, Lon: loc.Lng}
+		addresses[i] = results[0].FormattedAddress
+	}
+	return locations, addresses, nil
+}
+
+// parseLatLng parses place as a "lat,lng" pair.
+func parseLatLng(place string) (lat, lng float64, ok bool) {
+	parts := strings.SplitN(place, ",", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	lng, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+// valhallaCosting maps a Google TravelMode onto the closest Valhalla costing
+// model.
+func valhallaCosting(mode TravelMode) string {
+	switch mode {
+	case TravelModeWalking:
+		return "pedestrian"
+	case TravelModeBicycling:
+		return "bicycle"
+	case TravelModeTransit:
+		// Valhalla has no direct transit costing; multimodal is the closest
+		// available fit.
+		return "multimodal"
+	default:
+		return "auto"
+	}
+}
+
+// valhallaCostingOptions maps Avoid onto the costing_options Valhalla
+// expects nested under the active costing model's name.
+func valhallaCostingOptions(r *DistanceMatrixRequest) map[string]interface{} {
+	costing := valhallaCosting(r.Mode)
+	opts := map[string]interface{}{}
+	switch r.Avoid {
+	case AvoidTolls:
+		opts["use_tolls"] = 0.0
+	case AvoidHighways:
+		opts["use_highways"] = 0.0
+	case AvoidFerries:
+		opts["use_ferry"] = 0.0
+	}
+	if len(opts) == 0 {
+		return nil
+	}
+	return map[string]interface{}{costing: opts}
+}
+
+// valhallaDateTimeLayout is the local ISO form Valhalla's date_time.value
+// expects, e.g. "2006-01-02T15:04".
+const valhallaDateTimeLayout = "2006-01-02T15:04"
+
+// valhallaDateTimeFor maps DistanceMatrixRequest.DepartureTime onto
+// Valhalla's date_time type/value pair. Valhalla has no equivalent of
+// Google's "now" sentinel, so it is mapped to type 0 (current departure).
+// Google's DepartureTime is otherwise Unix seconds, which Valhalla does not
+// understand, so it is converted to Valhalla's local ISO layout.
+func valhallaDateTimeFor(departureTime string) *valhallaDateTime {
+	switch departureTime {
+	case "":
+		return nil
+	case "now":
+		return &valhallaDateTime{Type: 0}
+	default:
+		if secs, err := strconv.ParseInt(departureTime, 10, 64); err == nil {
+			return &valhallaDateTime{Type: 1, Value: time.Unix(secs, 0).Format(valhallaDateTimeLayout)}
+		}
+		// Not a Unix timestamp; assume the caller already passed Valhalla's
+		// expected local ISO form directly.
+		return &valhallaDateTime{Type: 1, Value: departureTime}
+	}
+}
+
+// valhallaElement converts a Valhalla matrix cell into a
+// DistanceMatrixElement. An unreachable pair (a populated cell with null
+// distance/time) and a bare null cell are both treated as NOT_FOUND.
+func valhallaElement(cell *valhallaMatrixCell) *DistanceMatrixElement {
+	if cell == nil || cell.Distance == nil || cell.Time == nil {
+		return &DistanceMatrixElement{Status: "NOT_FOUND"}
+	}
+	return &DistanceMatrixElement{
+		Status:   "OK",
+		Duration: time.Duration(*cell.Time) * time.Second,
+		Distance: Distance{
+			Meters:        int(*cell.Distance * 1000),
+			HumanReadable: fmt.Sprintf("%.1f km", *cell.Distance),
+		},
+	}
+}
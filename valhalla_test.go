@@ -0,0 +1,108 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maps
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestValhallaProviderDistanceMatrix(t *testing.T) {
+	// The second cell is a bare JSON null (no route at all); the third is
+	// the shape a real Valhalla instance actually returns for an
+	// unreachable pair: a populated cell with null distance/time.
+	response := `{
+   "sources_to_targets" : [
+      [ { "distance" : 23.8, "time" : 2215 }, null, { "distance" : null, "time" : null, "from_index" : 0, "to_index" : 2 } ]
+   ]
+}`
+	server := mockServer(200, response)
+	defer server.Close()
+
+	provider := NewValhallaProvider(server.URL)
+	c, err := NewClient(WithRoutingProvider(provider))
+	if err != nil {
+		t.Fatalf("NewClient returned error: %+v", err)
+	}
+
+	r := &DistanceMatrixRequest{
+		Origins:      []string{"-33.8688,151.2093"},
+		Destinations: []string{"-33.8150,151.0011", "0,0", "90,0"},
+	}
+
+	resp, err := c.DistanceMatrix(context.Background(), r)
+	if err != nil {
+		t.Fatalf("DistanceMatrix returned error: %+v", err)
+	}
+	if len(resp.Rows) != 1 || len(resp.Rows[0].Elements) != 3 {
+		t.Fatalf("unexpected response shape: %+v", resp)
+	}
+
+	got := resp.Rows[0].Elements[0]
+	if got.Status != "OK" {
+		t.Errorf("Status = %q, want OK", got.Status)
+	}
+	if got.Duration != 2215*time.Second {
+		t.Errorf("Duration = %v, want %v", got.Duration, 2215*time.Second)
+	}
+	if got.Distance.Meters != 23800 {
+		t.Errorf("Distance.Meters = %d, want 23800", got.Distance.Meters)
+	}
+
+	if nullCell := resp.Rows[0].Elements[1]; nullCell.Status != "NOT_FOUND" {
+		t.Errorf("Status = %q, want NOT_FOUND for a null cell", nullCell.Status)
+	}
+	if nullFields := resp.Rows[0].Elements[2]; nullFields.Status != "NOT_FOUND" {
+		t.Errorf("Status = %q, want NOT_FOUND for a populated cell with null distance/time", nullFields.Status)
+	}
+}
+
+func TestValhallaDateTimeFor(t *testing.T) {
+	if got := valhallaDateTimeFor(""); got != nil {
+		t.Errorf("valhallaDateTimeFor(\"\") = %+v, want nil", got)
+	}
+	if got := valhallaDateTimeFor("now"); got == nil || got.Type != 0 {
+		t.Errorf("valhallaDateTimeFor(\"now\") = %+v, want {Type: 0}", got)
+	}
+
+	// 2021-01-02T03:04:00Z, given as Unix seconds, must become Valhalla's
+	// local ISO layout rather than being passed through verbatim.
+	got := valhallaDateTimeFor("1609556640")
+	if got == nil || got.Type != 1 {
+		t.Fatalf("valhallaDateTimeFor(epoch) = %+v, want {Type: 1, ...}", got)
+	}
+	want := time.Unix(1609556640, 0).Format(valhallaDateTimeLayout)
+	if got.Value != want {
+		t.Errorf("Value = %q, want %q", got.Value, want)
+	}
+}
+
+func TestValhallaProviderRequiresGeocoderForAddresses(t *testing.T) {
+	provider := NewValhallaProvider("http://localhost:8002")
+	c, err := NewClient(WithRoutingProvider(provider))
+	if err != nil {
+		t.Fatalf("NewClient returned error: %+v", err)
+	}
+
+	r := &DistanceMatrixRequest{
+		Origins:      []string{"Sydney"},
+		Destinations: []string{"Parramatta"},
+	}
+
+	if _, err := c.DistanceMatrix(context.Background(), r); err == nil {
+		t.Error("expected an error geocoding an address with no geocoder configured")
+	}
+}